@@ -0,0 +1,89 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Command thumbnailer runs the thumbnail pipeline as a standalone
+// worker: a gRPC service plus an HTTP/JSON gateway, so multiple
+// YukkiMusic bot instances can share one pool instead of each paying
+// the overlay/render cost themselves.
+package main
+
+import (
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"github.com/Laky-64/gologging"
+
+	"github.com/TheTeamVivek/YukkiMusic/internal/utils/thumbnail"
+	"github.com/TheTeamVivek/YukkiMusic/internal/utils/thumbnail/thumbnailerpb"
+)
+
+func main() {
+	grpcAddr := flag.String("grpc-addr", ":50051", "address the gRPC Thumbnailer service listens on")
+	httpAddr := flag.String("http-addr", ":8088", "address the HTTP/JSON gateway listens on")
+	flag.Parse()
+
+	logger := gologging.GetLogger("Thumbnailer")
+	srv := thumbnail.NewServer()
+
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		logger.ErrorF("Failed to listen on %s: %v", *grpcAddr, err)
+		os.Exit(1)
+	}
+
+	grpcServer := grpc.NewServer()
+	thumbnailerpb.RegisterThumbnailerServer(grpcServer, srv)
+
+	go func() {
+		logger.InfoF("gRPC Thumbnailer listening on %s", *grpcAddr)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.ErrorF("gRPC server stopped: %v", err)
+		}
+	}()
+
+	httpServer := &http.Server{Addr: *httpAddr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/thumbnail" {
+			http.NotFound(w, r)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	})}
+
+	go func() {
+		logger.InfoF("HTTP thumbnail gateway listening on %s", *httpAddr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.ErrorF("HTTP server stopped: %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	logger.InfoF("Shutting down thumbnailer")
+	grpcServer.GracefulStop()
+	httpServer.Close()
+}
@@ -0,0 +1,81 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// defaultFontData is DejaVu Sans Bold, embedded so thumbnail rendering no
+// longer depends on whatever fonts happen to be installed on the host.
+//
+//go:embed assets/DejaVuSans-Bold.ttf
+var defaultFontData []byte
+
+var (
+	defaultFont     *opentype.Font
+	registeredFonts sync.Map // name string -> *opentype.Font
+)
+
+func init() {
+	f, err := opentype.Parse(defaultFontData)
+	if err != nil {
+		panic(fmt.Errorf("utils: failed to parse embedded default font: %w", err))
+	}
+	defaultFont = f
+	registeredFonts.Store("", f)
+}
+
+// RegisterFont parses TrueType/OpenType font data once and makes it
+// available under name for ThumbnailConfig.FontName, so callers can ship
+// a font covering CJK, Cyrillic, or other scripts the embedded default
+// doesn't, without YukkiMusic reaching back out to the filesystem.
+func RegisterFont(name string, data []byte) error {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %q: %w", name, err)
+	}
+	registeredFonts.Store(name, f)
+	return nil
+}
+
+// lookupFont returns the font registered under name, falling back to the
+// embedded DejaVu Sans Bold when name is empty or unknown.
+func lookupFont(name string) *opentype.Font {
+	if f, ok := registeredFonts.Load(name); ok {
+		return f.(*opentype.Font)
+	}
+	return defaultFont
+}
+
+// newFontFace builds a hinted, kerned font.Face at size from f, replacing
+// the old per-call gg.LoadFontFace-from-disk path.
+func newFontFace(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
@@ -1,20 +1,22 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Laky-64/gologging"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 
 	"main/internal/state"
@@ -29,11 +31,19 @@ func GenThumb(track *state.Track) string {
 
 	os.MkdirAll(cacheDir, 0o755)
 
+	// Check the cache before doing any network work, same as before
+	// animated-GIF support was added. Also check the .gif extension an
+	// animated source would have been cached under, so a cached
+	// animated thumbnail doesn't still pay for an artwork download and
+	// a loadTrackWaveform (potentially a full ffmpeg decode) below.
 	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%s.png", track.ID))
-
 	if _, err := os.Stat(cachePath); err == nil {
 		return cachePath
 	}
+	gifPath := filepath.Join(cacheDir, fmt.Sprintf("%s.gif", track.ID))
+	if _, err := os.Stat(gifPath); err == nil {
+		return gifPath
+	}
 
 	title := track.Title
 	artist := "Vivek"
@@ -57,6 +67,14 @@ func GenThumb(track *state.Track) string {
 		return ""
 	}
 
+	waveform := loadTrackWaveform(track)
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "gif") {
+		if g, err := gif.DecodeAll(bytes.NewReader(imgData)); err == nil && len(g.Image) > 1 {
+			return genAnimatedThumb(track, g, title, artist, duration, waveform)
+		}
+	}
+
 	thumbPath := filepath.Join(cacheDir, fmt.Sprintf("thumb_%s.jpg", track.ID))
 	err = ioutil.WriteFile(thumbPath, imgData, 0o644)
 	if err != nil {
@@ -79,26 +97,81 @@ func GenThumb(track *state.Track) string {
 		return ""
 	}
 
+	base := renderThumbFrame(img, title, artist, duration, waveform, thumbCardColors(img))
+
+	os.Remove(thumbPath)
+	outFile, err := os.Create(cachePath)
+	if err != nil {
+		return ""
+	}
+	defer outFile.Close()
+	png.Encode(outFile, base)
+
+	return cachePath
+}
+
+// cardColors holds the artwork-derived background/wave colors shared by
+// every frame rendered onto a single "now playing" card, so the palette
+// is only ever extracted once per track (see thumbCardColors) instead of
+// reseeding k-means per animated frame.
+type cardColors struct {
+	Background color.RGBA
+	Wave       color.RGBA
+}
+
+// thumbCardColors extracts cardColors from album once. For an animated
+// source, call this with the first frame and reuse the result across
+// every other frame: re-extracting per frame would let near-duplicate
+// consecutive frames land on different cluster arrangements, making the
+// card's background/wave color flicker across the animation.
+func thumbCardColors(album image.Image) cardColors {
+	pal := ExtractPalette(album, defaultPaletteSize)
+	return cardColors{
+		Background: DarkestColor(pal, color.RGBA{18, 27, 33, 255}),
+		Wave:       MostSaturatedColor(pal, color.RGBA{28, 37, 45, 255}),
+	}
+}
+
+// renderThumbFrame composites a single album cover image onto the
+// 1920x1080 "now playing" card (background, wave strip, title/artist/
+// duration text). Shared by the static and animated GenThumb paths so
+// every frame of an animated source gets identical text placement and
+// the same colors (see thumbCardColors). waveform is the real per-track
+// waveform from loadTrackWaveform; a nil or empty slice falls back to the
+// flat decorative band this card always used to draw.
+func renderThumbFrame(album image.Image, title, artist string, duration int, waveform []float32, colors cardColors) *image.RGBA {
 	const W, H = 1920, 1080
 	base := image.NewRGBA(image.Rect(0, 0, W, H))
-	bgColor := color.RGBA{18, 27, 33, 255}
-	draw.Draw(base, base.Bounds(), &image.Uniform{C: bgColor}, image.Point{}, draw.Src)
 
-	waveColor := color.RGBA{28, 37, 45, 255}
+	draw.Draw(base, base.Bounds(), &image.Uniform{C: colors.Background}, image.Point{}, draw.Src)
+
 	waveRect := image.Rect(0, H-400, W, H)
-	draw.Draw(base, waveRect, &image.Uniform{C: waveColor}, image.Point{}, draw.Over)
+	if len(waveform) > 0 {
+		drawWaveformStrip(base, waveform, waveRect)
+	} else {
+		draw.Draw(base, waveRect, &image.Uniform{C: colors.Wave}, image.Point{}, draw.Over)
+	}
 
-	album := image.NewRGBA(image.Rect(0, 0, 650, 650))
-	draw.CatmullRom.Scale(album, album.Bounds(), img, img.Bounds(), draw.Over, nil)
+	cover := image.NewRGBA(image.Rect(0, 0, 650, 650))
+	draw.CatmullRom.Scale(cover, cover.Bounds(), album, album.Bounds(), draw.Over, nil)
 
-	draw.Draw(base, image.Rect(180, 220, 180+650, 220+650), album, image.Point{}, draw.Over)
+	draw.Draw(base, image.Rect(180, 220, 180+650, 220+650), cover, image.Point{}, draw.Over)
 
-	face := basicfont.Face7x13
+	labelFace, err := newFontFace(defaultFont, 28)
+	if err != nil {
+		gologging.ErrorF("Failed to build label font face %v", err)
+		return base
+	}
+	titleFace, err := newFontFace(defaultFont, 48)
+	if err != nil {
+		gologging.ErrorF("Failed to build title font face %v", err)
+		return base
+	}
 
 	drawer := &font.Drawer{
 		Dst:  base,
 		Src:  image.NewUniform(color.RGBA{185, 192, 199, 255}), // light grey color
-		Face: face,
+		Face: labelFace,
 		Dot: fixed.Point26_6{
 			X: fixed.I(900),
 			Y: fixed.I(330),
@@ -109,11 +182,13 @@ func GenThumb(track *state.Track) string {
 	drawer.DrawString("Playing")
 
 	// Draw the track title (white color)
+	drawer.Face = titleFace
 	drawer.Src = image.NewUniform(color.White)
 	drawer.Dot = fixed.Point26_6{X: fixed.I(900), Y: fixed.I(420)}
 	drawer.DrawString(title)
 
 	// Draw the artist name (light grey)
+	drawer.Face = labelFace
 	drawer.Src = image.NewUniform(color.RGBA{205, 205, 205, 255})
 	drawer.Dot = fixed.Point26_6{X: fixed.I(900), Y: fixed.I(550)}
 	drawer.DrawString(artist)
@@ -123,13 +198,88 @@ func GenThumb(track *state.Track) string {
 	drawer.Dot = fixed.Point26_6{X: fixed.I(900), Y: fixed.I(650)}
 	drawer.DrawString(fmt.Sprintf("Duration: %d", duration))
 
-	os.Remove(thumbPath)
+	return base
+}
+
+// genAnimatedThumb renders every frame of an animated source artwork
+// (e.g. an animated GIF album cover) onto its own "now playing" card and
+// writes the result out as an animated GIF, caching the result the same
+// way the static path does.
+func genAnimatedThumb(track *state.Track, src *gif.GIF, title, artist string, duration int, waveform []float32) string {
+	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%s.gif", track.ID))
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath
+	}
+
+	// Extract colors once from the first frame and reuse them for every
+	// frame, rather than reseeding k-means per frame (see thumbCardColors).
+	colors := thumbCardColors(src.Image[0])
+
+	frames := make([]*image.RGBA, len(src.Image))
+	for i, frame := range src.Image {
+		frames[i] = renderThumbFrame(frame, title, artist, duration, waveform, colors)
+	}
+
+	palette := sharedPalette(frames)
+	out := make([]*image.Paletted, len(frames))
+	for i, frame := range frames {
+		paletted := image.NewPaletted(frame.Bounds(), palette)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+		out[i] = paletted
+	}
+
 	outFile, err := os.Create(cachePath)
 	if err != nil {
+		gologging.ErrorF("Failed to create animated thumbnail file %v", err)
 		return ""
 	}
 	defer outFile.Close()
-	png.Encode(outFile, base)
+
+	if err := gif.EncodeAll(outFile, &gif.GIF{Image: out, Delay: src.Delay, LoopCount: 0}); err != nil {
+		gologging.ErrorF("Failed to encode animated thumbnail %v", err)
+		return ""
+	}
 
 	return cachePath
 }
+
+// loadTrackWaveform fetches (and caches) the real waveform for track's
+// audio stream, returning nil when the track has no stream URL or the
+// computation fails so callers fall back to the flat decorative band.
+func loadTrackWaveform(track *state.Track) []float32 {
+	if track.StreamURL == "" {
+		return nil
+	}
+
+	waveform, err := LoadOrComputeWaveform(track.ID, track.StreamURL, defaultWaveformSize)
+	if err != nil {
+		gologging.ErrorF("Failed to compute waveform for %s: %v", track.ID, err)
+		return nil
+	}
+	return waveform
+}
+
+// drawWaveformStrip draws waveform as vertical bars across rect,
+// replacing the flat waveColor band this card used to draw.
+func drawWaveformStrip(base *image.RGBA, waveform []float32, rect image.Rectangle) {
+	barColor := color.RGBA{90, 185, 210, 255}
+	n := len(waveform)
+
+	barWidth := rect.Dx() / n
+	if barWidth < 1 {
+		barWidth = 1
+	}
+	mid := rect.Min.Y + rect.Dy()/2
+
+	for i, v := range waveform {
+		h := int(float64(v) * float64(rect.Dy()) * 0.9)
+		x0 := rect.Min.X + i*barWidth
+		x1 := x0 + barWidth - 2
+		if x1 <= x0 {
+			x1 = x0 + 1
+		}
+
+		barRect := image.Rect(x0, mid-h/2, x1, mid+h/2)
+		draw.Draw(base, barRect, &image.Uniform{C: barColor}, image.Point{}, draw.Over)
+	}
+}
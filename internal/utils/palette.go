@@ -0,0 +1,300 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// paletteDownsampleSize bounds the grid ExtractPalette samples from,
+	// so clustering a 3000x3000 cover doesn't cost more than a thumbnail
+	// itself is worth.
+	paletteDownsampleSize = 100
+	paletteMaxSamples     = 20000
+	paletteMaxIterations  = 10
+	defaultPaletteSize    = 5
+)
+
+type rgbSample [3]float64
+
+// ExtractPalette clusters img's colors into k dominant swatches via
+// k-means (k-means++ seeded), returning them sorted largest-cluster
+// first. k <= 0 defaults to 5; k is capped to the number of samples
+// available for tiny images.
+func ExtractPalette(img image.Image, k int) []color.RGBA {
+	if k <= 0 {
+		k = defaultPaletteSize
+	}
+
+	samples := downsamplePixels(img)
+	if len(samples) == 0 {
+		return nil
+	}
+	if k > len(samples) {
+		k = len(samples)
+	}
+
+	rng := rand.New(rand.NewSource(sampleSeed(samples)))
+	centroids := kmeansPlusPlusInit(samples, k, rng)
+
+	assignments := make([]int, len(samples))
+	var counts []int
+	for iter := 0; iter < paletteMaxIterations; iter++ {
+		changed := assignClusters(samples, centroids, assignments)
+		counts = recomputeCentroids(samples, assignments, centroids)
+		if !changed {
+			break
+		}
+	}
+
+	type cluster struct {
+		color rgbSample
+		count int
+	}
+	clusters := make([]cluster, 0, len(centroids))
+	for i, c := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+		clusters = append(clusters, cluster{color: c, count: counts[i]})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].count > clusters[j].count })
+
+	result := make([]color.RGBA, len(clusters))
+	for i, c := range clusters {
+		result[i] = c.color.toRGBA()
+	}
+	return result
+}
+
+// DarkestColor returns the lowest-luminance swatch in pal, falling back
+// to fallback when pal is empty.
+func DarkestColor(pal []color.RGBA, fallback color.RGBA) color.RGBA {
+	if len(pal) == 0 {
+		return fallback
+	}
+	darkest := pal[0]
+	darkestLum := luminance(darkest)
+	for _, c := range pal[1:] {
+		if lum := luminance(c); lum < darkestLum {
+			darkest, darkestLum = c, lum
+		}
+	}
+	return darkest
+}
+
+// MostSaturatedColor returns the most vivid (HSV-saturation) swatch in
+// pal, falling back to fallback when pal is empty.
+func MostSaturatedColor(pal []color.RGBA, fallback color.RGBA) color.RGBA {
+	if len(pal) == 0 {
+		return fallback
+	}
+	best := pal[0]
+	bestSat := saturation(best)
+	for _, c := range pal[1:] {
+		if sat := saturation(c); sat > bestSat {
+			best, bestSat = c, sat
+		}
+	}
+	return best
+}
+
+// TextColorFor picks black or white, whichever reads better on bg, via
+// the standard 0.299R+0.587G+0.114B luminance threshold.
+func TextColorFor(bg color.RGBA) color.Color {
+	if luminance(bg) > 128 {
+		return color.Black
+	}
+	return color.White
+}
+
+func luminance(c color.RGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+func saturation(c color.RGBA) float64 {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	if max == 0 {
+		return 0
+	}
+	return (max - min) / max
+}
+
+// downsamplePixels nearest-neighbor-downsamples img to at most
+// paletteDownsampleSize per side and returns up to paletteMaxSamples RGB
+// samples.
+func downsamplePixels(img image.Image) []rgbSample {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+
+	dw, dh := w, h
+	if dw > paletteDownsampleSize {
+		dw = paletteDownsampleSize
+	}
+	if dh > paletteDownsampleSize {
+		dh = paletteDownsampleSize
+	}
+
+	samples := make([]rgbSample, 0, dw*dh)
+	for y := 0; y < dh; y++ {
+		sy := bounds.Min.Y + y*h/dh
+		for x := 0; x < dw; x++ {
+			sx := bounds.Min.X + x*w/dw
+			r, g, b, _ := img.At(sx, sy).RGBA()
+			samples = append(samples, rgbSample{float64(r >> 8), float64(g >> 8), float64(b >> 8)})
+			if len(samples) >= paletteMaxSamples {
+				return samples
+			}
+		}
+	}
+	return samples
+}
+
+// sampleSeed derives a seed from the sampled pixels so the same artwork
+// always clusters the same way.
+func sampleSeed(samples []rgbSample) int64 {
+	h := fnv.New64a()
+	for i := 0; i < len(samples) && i < 256; i++ {
+		s := samples[i]
+		h.Write([]byte{byte(s[0]), byte(s[1]), byte(s[2])})
+	}
+	return int64(h.Sum64())
+}
+
+// kmeansPlusPlusInit picks k initial centroids: the first uniformly at
+// random, each subsequent one with probability proportional to its
+// squared distance from the nearest centroid chosen so far.
+func kmeansPlusPlusInit(samples []rgbSample, k int, rng *rand.Rand) []rgbSample {
+	centroids := make([]rgbSample, 0, k)
+	centroids = append(centroids, samples[rng.Intn(len(samples))])
+
+	distSq := make([]float64, len(samples))
+	for len(centroids) < k {
+		var total float64
+		for i, s := range samples {
+			d := nearestDistSq(s, centroids)
+			distSq[i] = d
+			total += d
+		}
+		if total == 0 {
+			centroids = append(centroids, samples[rng.Intn(len(samples))])
+			continue
+		}
+
+		target := rng.Float64() * total
+		var cum float64
+		chosen := samples[len(samples)-1]
+		for i, s := range samples {
+			cum += distSq[i]
+			if cum >= target {
+				chosen = s
+				break
+			}
+		}
+		centroids = append(centroids, chosen)
+	}
+	return centroids
+}
+
+// assignClusters assigns every sample to its nearest centroid, reporting
+// whether any assignment changed from its previous value.
+func assignClusters(samples []rgbSample, centroids []rgbSample, assignments []int) bool {
+	changed := false
+	for i, s := range samples {
+		best := 0
+		bestDist := math.MaxFloat64
+		for c, centroid := range centroids {
+			if d := distSq(s, centroid); d < bestDist {
+				bestDist, best = d, c
+			}
+		}
+		if assignments[i] != best {
+			assignments[i] = best
+			changed = true
+		}
+	}
+	return changed
+}
+
+// recomputeCentroids replaces each centroid with the mean of its
+// assigned samples (leaving it in place if it has none) and returns the
+// per-centroid cluster sizes.
+func recomputeCentroids(samples []rgbSample, assignments []int, centroids []rgbSample) []int {
+	sums := make([]rgbSample, len(centroids))
+	counts := make([]int, len(centroids))
+	for i, s := range samples {
+		c := assignments[i]
+		sums[c][0] += s[0]
+		sums[c][1] += s[1]
+		sums[c][2] += s[2]
+		counts[c]++
+	}
+	for i := range centroids {
+		if counts[i] > 0 {
+			centroids[i] = rgbSample{
+				sums[i][0] / float64(counts[i]),
+				sums[i][1] / float64(counts[i]),
+				sums[i][2] / float64(counts[i]),
+			}
+		}
+	}
+	return counts
+}
+
+func distSq(a, b rgbSample) float64 {
+	dr := a[0] - b[0]
+	dg := a[1] - b[1]
+	db := a[2] - b[2]
+	return dr*dr + dg*dg + db*db
+}
+
+func nearestDistSq(s rgbSample, centroids []rgbSample) float64 {
+	best := math.MaxFloat64
+	for _, c := range centroids {
+		if d := distSq(s, c); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+func (s rgbSample) toRGBA() color.RGBA {
+	clamp := func(v float64) uint8 {
+		if v < 0 {
+			return 0
+		}
+		if v > 255 {
+			return 255
+		}
+		return uint8(v)
+	}
+	return color.RGBA{clamp(s[0]), clamp(s[1]), clamp(s[2]), 255}
+}
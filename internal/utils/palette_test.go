@@ -0,0 +1,121 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// twoColorImage builds a 100x100 image split at column split: red on
+// the left, blue on the right, so ExtractPalette's clustering has an
+// unambiguous expected answer.
+func twoColorImage(split int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(img, image.Rect(0, 0, split, 100), &image.Uniform{C: color.RGBA{220, 20, 20, 255}}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(split, 0, 100, 100), &image.Uniform{C: color.RGBA{20, 20, 220, 255}}, image.Point{}, draw.Src)
+	return img
+}
+
+func closeU8(a, b uint8, tolerance int) bool {
+	d := int(a) - int(b)
+	if d < 0 {
+		d = -d
+	}
+	return d <= tolerance
+}
+
+func TestExtractPaletteTwoColorImage(t *testing.T) {
+	img := twoColorImage(70) // red covers the larger cluster
+	pal := ExtractPalette(img, 2)
+	if len(pal) != 2 {
+		t.Fatalf("expected 2 clusters, got %d: %v", len(pal), pal)
+	}
+
+	// Largest cluster (by pixel count) comes first.
+	red := pal[0]
+	blue := pal[1]
+	if !closeU8(red.R, 220, 10) || !closeU8(red.G, 20, 10) || !closeU8(red.B, 20, 10) {
+		t.Errorf("expected largest cluster close to red, got %+v", red)
+	}
+	if !closeU8(blue.R, 20, 10) || !closeU8(blue.G, 20, 10) || !closeU8(blue.B, 220, 10) {
+		t.Errorf("expected second cluster close to blue, got %+v", blue)
+	}
+}
+
+func TestExtractPaletteEmptyImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 0, 0))
+	if pal := ExtractPalette(img, 5); pal != nil {
+		t.Errorf("expected nil palette for an empty image, got %v", pal)
+	}
+}
+
+func TestExtractPaletteKCappedToSampleCount(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{10, 20, 30, 255})
+
+	pal := ExtractPalette(img, 5)
+	if len(pal) != 1 {
+		t.Fatalf("expected k to be capped to the single available sample, got %d clusters", len(pal))
+	}
+}
+
+func TestDarkestColor(t *testing.T) {
+	fallback := color.RGBA{1, 2, 3, 255}
+	if got := DarkestColor(nil, fallback); got != fallback {
+		t.Errorf("expected fallback for empty palette, got %+v", got)
+	}
+
+	pal := []color.RGBA{
+		{200, 200, 200, 255},
+		{10, 10, 10, 255},
+		{128, 128, 128, 255},
+	}
+	if got := DarkestColor(pal, fallback); got != pal[1] {
+		t.Errorf("expected darkest swatch %+v, got %+v", pal[1], got)
+	}
+}
+
+func TestMostSaturatedColor(t *testing.T) {
+	fallback := color.RGBA{1, 2, 3, 255}
+	if got := MostSaturatedColor(nil, fallback); got != fallback {
+		t.Errorf("expected fallback for empty palette, got %+v", got)
+	}
+
+	pal := []color.RGBA{
+		{128, 128, 128, 255}, // grey: zero saturation
+		{255, 0, 0, 255},     // fully saturated red
+		{200, 180, 180, 255}, // lightly saturated
+	}
+	if got := MostSaturatedColor(pal, fallback); got != pal[1] {
+		t.Errorf("expected most saturated swatch %+v, got %+v", pal[1], got)
+	}
+}
+
+func TestTextColorFor(t *testing.T) {
+	if got := TextColorFor(color.RGBA{0, 0, 0, 255}); got != color.White {
+		t.Errorf("expected white text on black background, got %v", got)
+	}
+	if got := TextColorFor(color.RGBA{255, 255, 255, 255}); got != color.Black {
+		t.Errorf("expected black text on white background, got %v", got)
+	}
+}
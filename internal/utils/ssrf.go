@@ -0,0 +1,143 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// maxFetchRedirects bounds how many redirects downloadImage's client will
+// follow before giving up, matching net/http's own default.
+const maxFetchRedirects = 10
+
+// newSafeHTTPClient builds an http.Client for fetching a single
+// caller-supplied artwork URL (and whatever it redirects to) without it
+// doubling as an SSRF proxy. This package is reachable as a standalone
+// network endpoint (see internal/utils/thumbnail), so "fetch whatever URL
+// the caller passes" would otherwise let a caller probe internal hosts or
+// metadata endpoints two ways: by pointing a domain at a public IP only
+// until it's validated then rebinding it to an internal one (DNS
+// rebinding), or by having an allowed URL redirect somewhere Go's default
+// client would follow unquestioned. pinnedHosts closes both: every host
+// is validated and its resolved IP pinned before any request reaches it,
+// redirects are re-validated and re-pinned per hop, and the dialer always
+// connects to the pinned IP instead of re-resolving the hostname.
+func newSafeHTTPClient(rawURL string) (*http.Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	pinned := &pinnedHosts{hosts: make(map[string]string)}
+	if err := pinned.validateAndPin(u); err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{DialContext: pinned.dialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			return pinned.validateAndPin(req.URL)
+		},
+	}, nil
+}
+
+// pinnedHosts remembers, for every host a fetch has been allowed to
+// reach, the single IP validateAndPin resolved and approved for it. The
+// dialer connects to that remembered IP rather than re-resolving the
+// hostname, so the address actually dialed can never diverge from the one
+// that was checked against isDisallowedIP.
+type pinnedHosts struct {
+	hosts map[string]string
+}
+
+// validateAndPin rejects u's scheme/host and, if it passes, records the
+// resolved IP it validated so dialContext can connect to exactly that
+// address later.
+func (p *pinnedHosts) validateAndPin(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("url has no host")
+	}
+
+	ip, err := resolveSafeIP(host)
+	if err != nil {
+		return err
+	}
+	p.hosts[host] = ip.String()
+	return nil
+}
+
+// dialContext connects to the IP validateAndPin already approved for
+// addr's host, never performing a DNS lookup of its own, so a host that
+// resolves differently between validation and connection can't change
+// where the TCP connection actually lands.
+func (p *pinnedHosts) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ip, ok := p.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("dial %s: host was never validated", host)
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+}
+
+// resolveSafeIP resolves host and rejects loopback, private, link-local,
+// or unspecified addresses - the ranges an SSRF probe would target.
+func resolveSafeIP(host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if isDisallowedIP(ip) {
+			return nil, fmt.Errorf("host %s is a disallowed address", host)
+		}
+		return ip, nil
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, fmt.Errorf("resolve host: %w", err)
+	}
+	for _, ip := range ips {
+		if isDisallowedIP(ip) {
+			return nil, fmt.Errorf("host %s resolves to a disallowed address (%s)", host, ip)
+		}
+	}
+	return ips[0], nil
+}
+
+func isDisallowedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified()
+}
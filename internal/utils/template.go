@@ -0,0 +1,131 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/fogleman/gg"
+	"github.com/nfnt/resize"
+)
+
+// TrackMeta carries the per-track data a ThumbnailTemplate needs to
+// render a layout, independent of how the artwork was downloaded or how
+// the result will be saved.
+type TrackMeta struct {
+	Title    string
+	Duration string
+	TrackID  string
+	Config   *ThumbnailConfig
+
+	// Waveform holds peak-normalized RMS buckets from ComputeWaveform /
+	// LoadOrComputeWaveform. Templates that don't use it (classic, vinyl)
+	// simply ignore it; waveform falls back to a deterministic fake when
+	// it's empty.
+	Waveform []float32
+	// PlaybackPosition is the current position as a fraction of the
+	// track's duration in [0,1]. Zero means "not currently playing" and
+	// waveform templates skip the progress split-color.
+	PlaybackPosition float64
+}
+
+// ThumbnailTemplate draws a complete thumbnail layout onto ctx, which is
+// already sized to cfg.Width/Height and pre-loaded with the artwork.
+type ThumbnailTemplate interface {
+	Render(ctx *gg.Context, meta TrackMeta) error
+}
+
+var (
+	templatesMu sync.RWMutex
+	templates   = map[string]ThumbnailTemplate{}
+)
+
+func init() {
+	RegisterTemplate("classic", classicTemplate{})
+	RegisterTemplate("vinyl", vinylTemplate{})
+	RegisterTemplate("waveform", waveformTemplate{})
+}
+
+// RegisterTemplate makes a ThumbnailTemplate selectable by name via
+// config.ThumbnailTemplate, so operators can ship custom layouts without
+// forking the overlay code.
+func RegisterTemplate(name string, t ThumbnailTemplate) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	templates[name] = t
+}
+
+// getTemplate resolves the template registered under name, falling back
+// to classic when name is empty or unknown.
+func getTemplate(name string) ThumbnailTemplate {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	if t, ok := templates[name]; ok {
+		return t
+	}
+	return templates["classic"]
+}
+
+// renderTemplate resizes img to fit meta.Config and runs tmpl over it.
+func renderTemplate(tmpl ThumbnailTemplate, img image.Image, meta TrackMeta) (image.Image, error) {
+	cfg := meta.Config
+	bounds := img.Bounds()
+	width := uint(bounds.Dx())
+	height := uint(bounds.Dy())
+
+	if width > cfg.Width || height > cfg.Height {
+		img = resize.Resize(cfg.Width, cfg.Height, img, resize.Lanczos3)
+	}
+
+	dc := gg.NewContextForImage(img)
+	if err := tmpl.Render(dc, meta); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return dc.Image(), nil
+}
+
+// renderTemplateAnimated runs tmpl over every frame of an animated
+// source and re-quantizes the result to a shared palette so the encoded
+// GIF doesn't balloon to one palette per frame.
+func renderTemplateAnimated(tmpl ThumbnailTemplate, frames []*image.Paletted, delays []int, meta TrackMeta) ([]*image.Paletted, []int, error) {
+	rendered := make([]*image.RGBA, len(frames))
+	for i, frame := range frames {
+		img, err := renderTemplate(tmpl, frame, meta)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to render frame %d: %w", i, err)
+		}
+		rendered[i] = toRGBA(img)
+	}
+
+	pal := sharedPalette(rendered)
+
+	out := make([]*image.Paletted, len(rendered))
+	for i, img := range rendered {
+		paletted := image.NewPaletted(img.Bounds(), pal)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.Point{})
+		out[i] = paletted
+	}
+
+	return out, delays, nil
+}
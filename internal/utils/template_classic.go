@@ -0,0 +1,112 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"image/color"
+
+	"github.com/fogleman/gg"
+)
+
+// classicTemplate is the original bottom-gradient + title + duration
+// overlay, kept as the default layout.
+type classicTemplate struct{}
+
+func (classicTemplate) Render(dc *gg.Context, meta TrackMeta) error {
+	cfg := meta.Config
+	width := float64(dc.Width())
+	height := float64(dc.Height())
+
+	// Add gradient overlay at bottom for better text visibility, shaded
+	// towards cfg.BackgroundColor (palette-adaptive, see ExtractPalette)
+	// instead of a fixed black.
+	bg, _ := cfg.BackgroundColor.(color.RGBA)
+	gradientHeight := height * 0.25
+	for i := 0.0; i < gradientHeight; i++ {
+		alpha := uint8((i / gradientHeight) * 180)
+		dc.SetColor(color.RGBA{bg.R, bg.G, bg.B, alpha})
+		dc.DrawRectangle(0, height-gradientHeight+i, width, 1)
+		dc.Fill()
+	}
+
+	// Calculate font size based on image dimensions
+	titleFontSize := width / 25.0
+	durationFontSize := width / 35.0
+
+	fontFace := lookupFont(cfg.FontName)
+
+	// Load font for title
+	if err := setFontFace(dc, fontFace, titleFontSize); err != nil {
+		logger.WarnF("Failed to load font, returning original image: %v", err)
+		return nil
+	}
+
+	// Draw title text
+	if meta.Title != "" {
+		// Wrap text if too long
+		maxWidth := width * 0.9
+		wrappedTitle := wrapText(dc, meta.Title, maxWidth)
+
+		// Draw text with shadow for better visibility
+		dc.SetColor(color.RGBA{0, 0, 0, 200}) // Shadow
+		x := width / 2
+		y := height - 60
+		dc.DrawStringAnchored(wrappedTitle, x+2, y+2, 0.5, 0.5)
+
+		dc.SetColor(textColorAt(dc, x, y, cfg.TextColor)) // Main text
+		dc.DrawStringAnchored(wrappedTitle, x, y, 0.5, 0.5)
+	}
+
+	// Draw duration text
+	if meta.Duration != "" {
+		// Load font for duration
+		if err := setFontFace(dc, fontFace, durationFontSize); err != nil {
+			logger.WarnF("Failed to load font for duration: %v", err)
+		} else {
+			dc.SetColor(color.RGBA{0, 0, 0, 200}) // Shadow
+			x := width - 20
+			y := height - 20
+			dc.DrawStringAnchored(meta.Duration, x+1, y+1, 1.0, 1.0)
+
+			dc.SetColor(textColorAt(dc, x, y, cfg.TextColor)) // Main text
+			dc.DrawStringAnchored(meta.Duration, x, y, 1.0, 1.0)
+		}
+	}
+
+	return nil
+}
+
+// textColorAt decides black-vs-white by the actual pixel dc has drawn
+// at (x, y) so far — i.e. after the gradient/artwork blend, not the raw
+// palette swatch the gradient was shaded from. Near the top of the
+// gradient band the blend can still be mostly the original artwork
+// pixel, which may be much brighter than cfg.BackgroundColor alone
+// would suggest. fallback is used if the point is out of bounds.
+func textColorAt(dc *gg.Context, x, y float64, fallback color.Color) color.Color {
+	img := dc.Image()
+	bounds := img.Bounds()
+	px, py := int(x), int(y)
+	if px < bounds.Min.X || px >= bounds.Max.X || py < bounds.Min.Y || py >= bounds.Max.Y {
+		return fallback
+	}
+
+	r, g, b, _ := img.At(px, py).RGBA()
+	return TextColorFor(color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255})
+}
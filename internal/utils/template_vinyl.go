@@ -0,0 +1,92 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// vinylTemplate masks the artwork into a circular "record", echoing the
+// 650x650 album crop GenThumb composites onto its now-playing card, and
+// adds a slightly tilted title band beneath it like a record label.
+type vinylTemplate struct{}
+
+func (vinylTemplate) Render(dc *gg.Context, meta TrackMeta) error {
+	cfg := meta.Config
+	width := float64(dc.Width())
+	height := float64(dc.Height())
+	cx, cy := width/2, height*0.42
+	radius := math.Min(width, height) * 0.33
+
+	// dc.Image() aliases the backing buffer dc draws into, so copy it
+	// before clearing or the clear would also blank out this reference.
+	artwork := image.NewRGBA(dc.Image().Bounds())
+	draw.Draw(artwork, artwork.Bounds(), dc.Image(), image.Point{}, draw.Src)
+
+	dc.SetColor(cfg.BackgroundColor)
+	dc.Clear()
+
+	dc.Push()
+	dc.DrawCircle(cx, cy, radius)
+	dc.Clip()
+	dc.DrawImage(artwork, 0, 0)
+	dc.Pop()
+
+	// Record rim and center spindle hole
+	dc.SetLineWidth(6)
+	dc.SetColor(color.RGBA{0, 0, 0, 220})
+	dc.DrawCircle(cx, cy, radius)
+	dc.Stroke()
+	dc.SetColor(color.RGBA{10, 10, 10, 255})
+	dc.DrawCircle(cx, cy, radius*0.08)
+	dc.Fill()
+
+	fontFace := lookupFont(cfg.FontName)
+
+	if meta.Title != "" {
+		if err := setFontFace(dc, fontFace, width/28.0); err != nil {
+			logger.WarnF("Failed to load font for vinyl title band: %v", err)
+			return nil
+		}
+
+		labelY := cy + radius + 60
+		dc.Push()
+		dc.RotateAbout(gg.Radians(-4), cx, labelY)
+		dc.SetColor(cfg.TextColor)
+		dc.DrawStringAnchored(wrapText(dc, meta.Title, width*0.85), cx, labelY, 0.5, 0.5)
+		dc.Pop()
+	}
+
+	if meta.Duration != "" {
+		if err := setFontFace(dc, fontFace, width/40.0); err != nil {
+			logger.WarnF("Failed to load font for vinyl duration: %v", err)
+			return nil
+		}
+		dc.SetColor(cfg.TextColor)
+		dc.DrawStringAnchored(meta.Duration, cx, cy+radius+100, 0.5, 0.5)
+	}
+
+	return nil
+}
@@ -24,18 +24,23 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Laky-64/gologging"
 	"github.com/fogleman/gg"
-	"github.com/nfnt/resize"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/webp"
 
 	"github.com/TheTeamVivek/YukkiMusic/config"
 )
@@ -43,27 +48,30 @@ import (
 var thumbnailCache = NewCache[string, string](30 * time.Minute)
 var logger = gologging.GetLogger("Thumbnail")
 
-// Common font paths for different systems
-var defaultFontPaths = []string{
-	"/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf",        // Debian/Ubuntu
-	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",             // Debian/Ubuntu
-	"/usr/share/fonts/dejavu/DejaVuSans-Bold.ttf",                 // Fedora/RHEL
-	"/usr/share/fonts/truetype/liberation/LiberationSans-Bold.ttf", // Alternative
-	"/System/Library/Fonts/Helvetica.ttc",                         // macOS
-	"/usr/share/fonts/truetype/ubuntu/Ubuntu-Bold.ttf",            // Ubuntu
-}
+// ThumbnailFormat selects the encoding used for a generated thumbnail.
+type ThumbnailFormat int
+
+const (
+	// FormatAuto picks PNG/JPG/GIF based on the decoded source image.
+	FormatAuto ThumbnailFormat = iota
+	FormatPNG
+	FormatJPG
+	FormatGIF
+)
 
 // ThumbnailConfig holds configuration for thumbnail customization
 type ThumbnailConfig struct {
 	AddOverlay      bool
 	OverlayText     string
-	TitleText       string
-	DurationText    string
 	BackgroundColor color.Color
 	TextColor       color.Color
 	Width           uint
 	Height          uint
 	Quality         int
+	Format          ThumbnailFormat
+	// FontName selects a font previously registered with RegisterFont.
+	// Empty uses the embedded DejaVu Sans Bold default.
+	FontName string
 }
 
 // DefaultThumbnailConfig returns the default configuration
@@ -75,17 +83,58 @@ func DefaultThumbnailConfig() *ThumbnailConfig {
 		Width:           1280,
 		Height:          720,
 		Quality:         85,
+		Format:          FormatAuto,
 	}
 }
 
 // ProcessThumbnail downloads and processes a thumbnail with custom overlay
 func ProcessThumbnail(thumbnailURL, title, duration string) (string, error) {
+	return ProcessThumbnailWithOptions(thumbnailURL, title, duration, ProcessThumbnailOptions{})
+}
+
+// ProcessThumbnailOptions augments ProcessThumbnail with extras most
+// callers don't need, like driving the waveform template off the real
+// audio instead of a deterministic fake.
+type ProcessThumbnailOptions struct {
+	// TrackID keys the waveform cache (cache/waveforms/<TrackID>.bin).
+	// Required for StreamURL to have any effect.
+	TrackID string
+	// StreamURL is the playable audio URL ComputeWaveform downsamples.
+	// Leave empty to skip real waveform computation entirely.
+	StreamURL string
+	// PlaybackPosition/TotalDuration derive the waveform template's
+	// progress split-color. Either left zero disables it.
+	PlaybackPosition time.Duration
+	TotalDuration    time.Duration
+
+	// Template overrides config.ThumbnailTemplate for this call when
+	// non-empty, so per-request callers (e.g. the thumbnailer service)
+	// don't have to mutate global config to pick a layout.
+	Template string
+	// Format overrides cfg.Format for this call when set to anything
+	// other than the zero value FormatAuto.
+	Format ThumbnailFormat
+	// Width/Height override DefaultThumbnailConfig's size when non-zero.
+	Width  uint
+	Height uint
+}
+
+// ProcessThumbnailWithOptions is ProcessThumbnail plus opts.
+func ProcessThumbnailWithOptions(thumbnailURL, title, duration string, opts ProcessThumbnailOptions) (string, error) {
 	if thumbnailURL == "" {
 		return "", nil
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("%s:%s:%s", thumbnailURL, title, duration)
+	// Check cache first. Every field that changes what gets rendered has
+	// to be part of the key, or two requests for the same url/title/
+	// duration but different opts (the chunk0-5 server's per-request
+	// template=/format= params, a different waveform/playback position,
+	// ...) would collide and the second one would silently get back the
+	// first one's render.
+	cacheKey := fmt.Sprintf("%s:%s:%s:%s:%d:%d:%d:%s:%s:%d:%d",
+		thumbnailURL, title, duration,
+		opts.Template, opts.Format, opts.Width, opts.Height,
+		opts.TrackID, opts.StreamURL, opts.PlaybackPosition, opts.TotalDuration)
 	if cached, ok := thumbnailCache.Get(cacheKey); ok {
 		if _, err := os.Stat(cached); err == nil {
 			return cached, nil
@@ -93,7 +142,7 @@ func ProcessThumbnail(thumbnailURL, title, duration string) (string, error) {
 	}
 
 	// Download original thumbnail
-	img, err := downloadImage(thumbnailURL)
+	src, err := downloadImage(thumbnailURL)
 	if err != nil {
 		logger.ErrorF("Failed to download thumbnail: %v", err)
 		return "", err
@@ -101,8 +150,8 @@ func ProcessThumbnail(thumbnailURL, title, duration string) (string, error) {
 
 	// If overlay is disabled, just save and return original
 	if !config.ThumbnailOverlay {
-		outputPath := generateOutputPath()
-		if err := saveImage(img, outputPath, 95); err != nil {
+		outputPath := generateOutputPath(src.format())
+		if err := saveSource(src, outputPath, 95); err != nil {
 			return "", err
 		}
 		thumbnailCache.Set(cacheKey, outputPath)
@@ -111,17 +160,69 @@ func ProcessThumbnail(thumbnailURL, title, duration string) (string, error) {
 
 	// Create custom thumbnail with overlay
 	cfg := DefaultThumbnailConfig()
-	cfg.TitleText = title
-	cfg.DurationText = duration
+	if opts.Format != FormatAuto {
+		cfg.Format = opts.Format
+	}
+	if opts.Width > 0 {
+		cfg.Width = opts.Width
+	}
+	if opts.Height > 0 {
+		cfg.Height = opts.Height
+	}
+
+	templateName := config.ThumbnailTemplate
+	if opts.Template != "" {
+		templateName = opts.Template
+	}
+	tmpl := getTemplate(templateName)
+
+	// Adapt the background/text colors to the artwork instead of using a
+	// fixed palette, so overlays stay legible across wildly different
+	// cover art.
+	if pal := ExtractPalette(src.Image, defaultPaletteSize); len(pal) > 0 {
+		bgFallback, _ := cfg.BackgroundColor.(color.RGBA)
+		bg := DarkestColor(pal, bgFallback)
+		cfg.BackgroundColor = color.RGBA{bg.R, bg.G, bg.B, bgFallback.A}
+		cfg.TextColor = TextColorFor(bg)
+	}
+
+	meta := TrackMeta{Title: title, Duration: duration, TrackID: cacheKey, Config: cfg}
+
+	if opts.StreamURL != "" && opts.TrackID != "" {
+		if waveform, err := LoadOrComputeWaveform(opts.TrackID, opts.StreamURL, defaultWaveformSize); err != nil {
+			logger.WarnF("Failed to compute waveform for %s: %v", opts.TrackID, err)
+		} else {
+			meta.Waveform = waveform
+			if opts.TotalDuration > 0 {
+				meta.PlaybackPosition = float64(opts.PlaybackPosition) / float64(opts.TotalDuration)
+			}
+		}
+	}
+
+	if src.Animated && cfg.Format != FormatJPG && cfg.Format != FormatPNG {
+		frames, delays, err := renderTemplateAnimated(tmpl, src.Frames, src.Delays, meta)
+		if err != nil {
+			logger.ErrorF("Failed to render animated thumbnail: %v", err)
+			return "", err
+		}
+
+		outputPath := generateOutputPath(FormatGIF)
+		if err := saveAnimatedGIF(frames, delays, outputPath); err != nil {
+			return "", err
+		}
 
-	processedImg, err := addOverlay(img, cfg)
+		thumbnailCache.Set(cacheKey, outputPath)
+		return outputPath, nil
+	}
+
+	processedImg, err := renderTemplate(tmpl, src.Image, meta)
 	if err != nil {
-		logger.ErrorF("Failed to add overlay: %v", err)
+		logger.ErrorF("Failed to render thumbnail: %v", err)
 		return "", err
 	}
 
 	// Save processed image
-	outputPath := generateOutputPath()
+	outputPath := generateOutputPath(cfg.Format)
 	if err := saveImage(processedImg, outputPath, cfg.Quality); err != nil {
 		return "", err
 	}
@@ -130,12 +231,40 @@ func ProcessThumbnail(thumbnailURL, title, duration string) (string, error) {
 	return outputPath, nil
 }
 
-// downloadImage downloads an image from a URL
-func downloadImage(url string) (image.Image, error) {
+// decodedSource holds an artwork download after format sniffing. For
+// animated sources (multi-frame GIF/WebP) both the decoded frame/delay
+// pairs and a flattened first-frame Image are populated so callers that
+// don't care about animation can keep using Image directly.
+type decodedSource struct {
+	Image    image.Image
+	Frames   []*image.Paletted
+	Delays   []int
+	Animated bool
+}
+
+// format reports the ThumbnailFormat this source should be saved as
+// unless the caller pins a different one.
+func (s *decodedSource) format() ThumbnailFormat {
+	if s.Animated {
+		return FormatGIF
+	}
+	return FormatJPG
+}
+
+// downloadImage downloads an image from a URL and decodes it, detecting
+// animated GIF/WebP artwork via the response Content-Type. The fetch
+// itself goes through newSafeHTTPClient, since url can be attacker-
+// controlled when this runs behind the standalone thumbnailer service.
+func downloadImage(url string) (*decodedSource, error) {
 	// Clean URL
 	url = CleanURL(url)
 
-	resp, err := http.Get(url)
+	client, err := newSafeHTTPClient(url)
+	if err != nil {
+		return nil, fmt.Errorf("refusing to fetch %q: %w", url, err)
+	}
+
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download image: %w", err)
 	}
@@ -151,107 +280,219 @@ func downloadImage(url string) (image.Image, error) {
 		return nil, fmt.Errorf("failed to read image data: %w", err)
 	}
 
+	contentType := resp.Header.Get("Content-Type")
+
+	if strings.Contains(contentType, "gif") {
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gif: %w", err)
+		}
+		if len(g.Image) > 1 {
+			return &decodedSource{Image: g.Image[0], Frames: g.Image, Delays: g.Delay, Animated: true}, nil
+		}
+		return &decodedSource{Image: g.Image[0]}, nil
+	}
+
+	if strings.Contains(contentType, "webp") {
+		// golang.org/x/image/webp only exposes the first frame of an
+		// animated WebP (no animation support upstream yet), so we fall
+		// back to a static thumbnail for those rather than misreporting
+		// them as animated.
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode webp: %w", err)
+		}
+		return &decodedSource{Image: img}, nil
+	}
+
 	// Decode image
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
 
-	return img, nil
+	return &decodedSource{Image: img}, nil
 }
 
-// loadFont attempts to load a font, trying custom path first, then default paths
-func loadFont(dc *gg.Context, fontSize float64) error {
-	// Try custom font first
-	customFont := config.ThumbnailFont
-	if customFont != "" && fileExists(customFont) {
-		if err := dc.LoadFontFace(customFont, fontSize); err == nil {
-			return nil
-		}
-		logger.WarnF("Failed to load custom font %s: trying defaults", customFont)
+// setFontFace builds a font.Face for f at fontSize and installs it on dc.
+func setFontFace(dc *gg.Context, f *opentype.Font, fontSize float64) error {
+	face, err := newFontFace(f, fontSize)
+	if err != nil {
+		return fmt.Errorf("failed to build font face: %w", err)
+	}
+	dc.SetFontFace(face)
+	return nil
+}
+
+// maxSharedPaletteColors is the GIF palette size limit.
+const maxSharedPaletteColors = 256
+
+// sharedPaletteSampleBudget bounds how many pixels sharedPalette reads
+// across all frames combined, so palette-building a long animation
+// doesn't cost more than rendering it did.
+const sharedPaletteSampleBudget = 50000
+
+// sharedPalette builds one median-cut palette from every frame's actual
+// pixels, so each GIF frame can reuse it instead of carrying its own
+// (keeping the encoded file size reasonable) without the banding and
+// color-shifting a fixed generic palette like palette.WebSafe causes on
+// photographic/artwork frames. Falls back to palette.WebSafe only when
+// frames is empty.
+func sharedPalette(frames []*image.RGBA) color.Palette {
+	pixels := sampleFramePixels(frames)
+	if len(pixels) == 0 {
+		return palette.WebSafe
+	}
+	return medianCutPalette(pixels, maxSharedPaletteColors)
+}
+
+// sampleFramePixels strides through every frame so the total number of
+// sampled pixels stays within sharedPaletteSampleBudget.
+func sampleFramePixels(frames []*image.RGBA) []color.RGBA {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	perFrame := sharedPaletteSampleBudget / len(frames)
+	if perFrame < 1 {
+		perFrame = 1
 	}
 
-	// Try default system fonts
-	for _, fontPath := range defaultFontPaths {
-		if fileExists(fontPath) {
-			if err := dc.LoadFontFace(fontPath, fontSize); err == nil {
-				return nil
+	var pixels []color.RGBA
+	for _, frame := range frames {
+		bounds := frame.Bounds()
+		total := bounds.Dx() * bounds.Dy()
+		if total == 0 {
+			continue
+		}
+		stride := total / perFrame
+		if stride < 1 {
+			stride = 1
+		}
+
+		i := 0
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if i%stride == 0 {
+					r, g, b, _ := frame.At(x, y).RGBA()
+					pixels = append(pixels, color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255})
+				}
+				i++
 			}
 		}
 	}
-
-	return fmt.Errorf("no suitable font found")
+	return pixels
 }
 
-// addOverlay adds text overlay to the thumbnail
-func addOverlay(img image.Image, cfg *ThumbnailConfig) (image.Image, error) {
-	// Resize image if needed
-	bounds := img.Bounds()
-	width := uint(bounds.Dx())
-	height := uint(bounds.Dy())
+// medianCutPalette repeatedly splits the bucket with the widest color
+// range along its widest channel until there are maxColors buckets (or
+// every bucket is down to one pixel), then averages each bucket.
+func medianCutPalette(pixels []color.RGBA, maxColors int) color.Palette {
+	buckets := [][]color.RGBA{pixels}
+
+	for len(buckets) < maxColors {
+		splitIdx, channel, widest := -1, 0, -1
+		for i, b := range buckets {
+			if len(b) < 2 {
+				continue
+			}
+			c, rng := widestChannelRange(b)
+			if rng > widest {
+				widest, splitIdx, channel = rng, i, c
+			}
+		}
+		if splitIdx == -1 {
+			break
+		}
 
-	if width > cfg.Width || height > cfg.Height {
-		img = resize.Resize(cfg.Width, cfg.Height, img, resize.Lanczos3)
-		bounds = img.Bounds()
-		width = uint(bounds.Dx())
-		height = uint(bounds.Dy())
+		lo, hi := splitBucket(buckets[splitIdx], channel)
+		buckets[splitIdx] = lo
+		buckets = append(buckets, hi)
 	}
 
-	// Create a new context for drawing
-	dc := gg.NewContextForImage(img)
-
-	// Add gradient overlay at bottom for better text visibility
-	gradientHeight := float64(height) * 0.25
-	for i := 0.0; i < gradientHeight; i++ {
-		alpha := uint8((i / gradientHeight) * 180)
-		dc.SetColor(color.RGBA{0, 0, 0, alpha})
-		dc.DrawRectangle(0, float64(height)-gradientHeight+i, float64(width), 1)
-		dc.Fill()
+	pal := make(color.Palette, len(buckets))
+	for i, b := range buckets {
+		pal[i] = averageColor(b)
 	}
+	return pal
+}
 
-	// Calculate font size based on image dimensions
-	titleFontSize := float64(width) / 25.0
-	durationFontSize := float64(width) / 35.0
+// widestChannelRange reports which of R/G/B varies most across pixels.
+func widestChannelRange(pixels []color.RGBA) (channel int, rng int) {
+	minR, minG, minB := uint8(255), uint8(255), uint8(255)
+	var maxR, maxG, maxB uint8
+	for _, p := range pixels {
+		minR, maxR = minU8(minR, p.R), maxU8(maxR, p.R)
+		minG, maxG = minU8(minG, p.G), maxU8(maxG, p.G)
+		minB, maxB = minU8(minB, p.B), maxU8(maxB, p.B)
+	}
 
-	// Load font for title
-	if err := loadFont(dc, titleFontSize); err != nil {
-		logger.WarnF("Failed to load font, returning original image: %v", err)
-		return img, nil
+	rR, rG, rB := int(maxR)-int(minR), int(maxG)-int(minG), int(maxB)-int(minB)
+	switch {
+	case rR >= rG && rR >= rB:
+		return 0, rR
+	case rG >= rB:
+		return 1, rG
+	default:
+		return 2, rB
 	}
+}
 
-	// Draw title text
-	if cfg.TitleText != "" {
-		// Wrap text if too long
-		maxWidth := float64(width) * 0.9
-		wrappedTitle := wrapText(dc, cfg.TitleText, maxWidth)
+// splitBucket sorts pixels by channel and divides them at the median.
+func splitBucket(pixels []color.RGBA, channel int) (lo, hi []color.RGBA) {
+	sorted := append([]color.RGBA(nil), pixels...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return channelValue(sorted[i], channel) < channelValue(sorted[j], channel)
+	})
+	mid := len(sorted) / 2
+	return sorted[:mid], sorted[mid:]
+}
 
-		// Draw text with shadow for better visibility
-		dc.SetColor(color.RGBA{0, 0, 0, 200}) // Shadow
-		x := float64(width) / 2
-		y := float64(height) - 60
-		dc.DrawStringAnchored(wrappedTitle, x+2, y+2, 0.5, 0.5)
+func channelValue(c color.RGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
 
-		dc.SetColor(cfg.TextColor) // Main text
-		dc.DrawStringAnchored(wrappedTitle, x, y, 0.5, 0.5)
+func averageColor(pixels []color.RGBA) color.Color {
+	var sumR, sumG, sumB int
+	for _, p := range pixels {
+		sumR += int(p.R)
+		sumG += int(p.G)
+		sumB += int(p.B)
 	}
+	n := len(pixels)
+	return color.RGBA{uint8(sumR / n), uint8(sumG / n), uint8(sumB / n), 255}
+}
 
-	// Draw duration text
-	if cfg.DurationText != "" {
-		// Load font for duration
-		if err := loadFont(dc, durationFontSize); err != nil {
-			logger.WarnF("Failed to load font for duration: %v", err)
-		} else {
-			dc.SetColor(color.RGBA{0, 0, 0, 200}) // Shadow
-			x := float64(width) - 20
-			y := float64(height) - 20
-			dc.DrawStringAnchored(cfg.DurationText, x+1, y+1, 1.0, 1.0)
+func minU8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+	return b
+}
 
-			dc.SetColor(color.RGBA{255, 255, 255, 255}) // Main text
-			dc.DrawStringAnchored(cfg.DurationText, x, y, 1.0, 1.0)
-		}
+func maxU8(a, b uint8) uint8 {
+	if a > b {
+		return a
 	}
+	return b
+}
 
-	return dc.Image(), nil
+// toRGBA flattens any image.Image into a concrete *image.RGBA so it can
+// be fed to the GIF quantizer/dither step.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
 }
 
 // wrapText wraps text to fit within maxWidth
@@ -328,17 +569,46 @@ func saveImage(img image.Image, path string, quality int) error {
 	return jpeg.Encode(file, img, &jpeg.Options{Quality: quality})
 }
 
-// generateOutputPath generates a unique output path for processed thumbnails
-func generateOutputPath() string {
-	timestamp := time.Now().UnixNano()
-	filename := fmt.Sprintf("thumb_%d.jpg", timestamp)
-	return filepath.Join(os.TempDir(), "yukki_thumbnails", filename)
+// saveSource saves a decodedSource as-is, preserving animation when present.
+func saveSource(src *decodedSource, path string, quality int) error {
+	if src.Animated {
+		return saveAnimatedGIF(src.Frames, src.Delays, path)
+	}
+	return saveImage(src.Image, path, quality)
 }
 
-// fileExists checks if a file exists
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+// saveAnimatedGIF writes a sequence of palettized frames out as an
+// animated GIF, looping forever.
+func saveAnimatedGIF(frames []*image.Paletted, delays []int, path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	return gif.EncodeAll(file, &gif.GIF{Image: frames, Delay: delays, LoopCount: 0})
+}
+
+// generateOutputPath generates a unique output path for processed
+// thumbnails, using the extension that matches format.
+func generateOutputPath(format ThumbnailFormat) string {
+	timestamp := time.Now().UnixNano()
+
+	ext := "jpg"
+	switch format {
+	case FormatPNG:
+		ext = "png"
+	case FormatGIF:
+		ext = "gif"
+	}
+
+	filename := fmt.Sprintf("thumb_%d.%s", timestamp, ext)
+	return filepath.Join(os.TempDir(), "yukki_thumbnails", filename)
 }
 
 // CleanupOldThumbnails removes old thumbnail files
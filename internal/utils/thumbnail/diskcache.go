@@ -0,0 +1,106 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package thumbnail
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DiskLRU caps the total size of a directory of rendered thumbnails,
+// evicting the least-recently-touched files first. The in-memory
+// thumbnailCache in utils already keeps hot paths around for a TTL; this
+// exists alongside it to bound disk usage when many bot instances share
+// one thumbnailer worker pool.
+type DiskLRU struct {
+	dir      string
+	maxBytes int64
+
+	mu sync.Mutex
+}
+
+// NewDiskLRU evicts from dir down to maxBytes whenever Touch pushes it
+// over. maxBytes <= 0 disables eviction entirely.
+func NewDiskLRU(dir string, maxBytes int64) *DiskLRU {
+	return &DiskLRU{dir: dir, maxBytes: maxBytes}
+}
+
+// Touch records that path was just produced/served, bumping its mtime so
+// it's evicted last, then trims the directory back under maxBytes.
+func (c *DiskLRU) Touch(path string) {
+	if c.maxBytes <= 0 {
+		return
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+}
+
+// evict removes the oldest files under c.dir until its total size is
+// back under maxBytes. Callers must hold c.mu.
+func (c *DiskLRU) evict() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []file
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		f := file{path: filepath.Join(c.dir, entry.Name()), size: info.Size(), modTime: info.ModTime()}
+		files = append(files, f)
+		total += f.size
+	}
+
+	if total <= c.maxBytes {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
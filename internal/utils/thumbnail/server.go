@@ -0,0 +1,193 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package thumbnail exposes utils' thumbnail pipeline as a standalone
+// worker: a gRPC service for bot instances and an HTTP/JSON gateway for
+// anything that'd rather not pull in a gRPC client. Both surfaces share
+// the same in-memory utils.ProcessThumbnail cache plus a DiskLRU capping
+// the on-disk footprint, so scaling the CPU-heavy overlay step out of
+// the Telegram-facing process doesn't also scale its disk usage.
+package thumbnail
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/TheTeamVivek/YukkiMusic/config"
+	"github.com/TheTeamVivek/YukkiMusic/internal/utils"
+	"github.com/TheTeamVivek/YukkiMusic/internal/utils/thumbnail/thumbnailerpb"
+)
+
+// streamChunkSize is how much of the encoded thumbnail each
+// GetThumbnailResponse carries, so a large animated GIF doesn't have to
+// be buffered whole on either side of the stream.
+const streamChunkSize = 32 * 1024
+
+// Server implements thumbnailerpb.ThumbnailerServer and doubles as the
+// http.Handler for the REST gateway.
+type Server struct {
+	thumbnailerpb.UnimplementedThumbnailerServer
+
+	cache *DiskLRU
+}
+
+// NewServer builds a Server whose disk cache is capped at
+// config.ThumbnailCacheBytes.
+func NewServer() *Server {
+	dir := filepath.Join(os.TempDir(), "yukki_thumbnails")
+	return &Server{cache: NewDiskLRU(dir, config.ThumbnailCacheBytes)}
+}
+
+// GetThumbnail renders req and streams the result back in chunks.
+func (s *Server) GetThumbnail(req *thumbnailerpb.GetThumbnailRequest, stream thumbnailerpb.Thumbnailer_GetThumbnailServer) error {
+	path, err := s.render(req)
+	if err != nil {
+		return status.Errorf(codes.Internal, "render thumbnail: %v", err)
+	}
+
+	return s.streamFile(path, stream)
+}
+
+// render runs the shared pipeline and touches the disk cache for path.
+// The artwork fetch itself (not just this entry point) is what's reachable
+// by an untrusted caller, so the SSRF guard lives with that fetch in
+// utils.downloadImage rather than being duplicated here.
+func (s *Server) render(req *thumbnailerpb.GetThumbnailRequest) (string, error) {
+	opts := utils.ProcessThumbnailOptions{
+		Template: req.Template,
+		Format:   fromProtoFormat(req.Format),
+		Width:    uint(req.Width),
+		Height:   uint(req.Height),
+	}
+
+	path, err := utils.ProcessThumbnailWithOptions(req.Url, req.Title, req.Duration, opts)
+	if err != nil {
+		return "", err
+	}
+
+	s.cache.Touch(path)
+	return path, nil
+}
+
+func (s *Server) streamFile(path string, stream thumbnailerpb.Thumbnailer_GetThumbnailServer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return status.Errorf(codes.Internal, "open rendered thumbnail: %v", err)
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	buf := make([]byte, streamChunkSize)
+	first := true
+
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			resp := &thumbnailerpb.GetThumbnailResponse{Chunk: append([]byte(nil), buf[:n]...)}
+			if first {
+				resp.ContentType = contentType
+				first = false
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return stream.Send(&thumbnailerpb.GetThumbnailResponse{Done: true})
+		}
+		if readErr != nil {
+			return status.Errorf(codes.Internal, "read rendered thumbnail: %v", readErr)
+		}
+	}
+}
+
+// ServeHTTP implements the REST gateway: GET /thumbnail?url=...&title=...
+// with the same fields as GetThumbnailRequest passed as query params.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	req := &thumbnailerpb.GetThumbnailRequest{
+		Url:      q.Get("url"),
+		Title:    q.Get("title"),
+		Duration: q.Get("duration"),
+		Template: q.Get("template"),
+		Format:   parseFormat(q.Get("format")),
+		Width:    parseUint(q.Get("width")),
+		Height:   parseUint(q.Get("height")),
+	}
+	if req.Url == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+
+	path, err := s.render(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("render thumbnail: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeFile(w, r, path)
+}
+
+func parseFormat(s string) thumbnailerpb.ThumbnailFormat {
+	switch s {
+	case "PNG":
+		return thumbnailerpb.ThumbnailFormat_FORMAT_PNG
+	case "JPG":
+		return thumbnailerpb.ThumbnailFormat_FORMAT_JPG
+	case "GIF":
+		return thumbnailerpb.ThumbnailFormat_FORMAT_GIF
+	default:
+		return thumbnailerpb.ThumbnailFormat_FORMAT_AUTO
+	}
+}
+
+func parseUint(s string) uint32 {
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(v)
+}
+
+func fromProtoFormat(f thumbnailerpb.ThumbnailFormat) utils.ThumbnailFormat {
+	switch f {
+	case thumbnailerpb.ThumbnailFormat_FORMAT_PNG:
+		return utils.FormatPNG
+	case thumbnailerpb.ThumbnailFormat_FORMAT_JPG:
+		return utils.FormatJPG
+	case thumbnailerpb.ThumbnailFormat_FORMAT_GIF:
+		return utils.FormatGIF
+	default:
+		return utils.FormatAuto
+	}
+}
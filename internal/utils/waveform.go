@@ -0,0 +1,183 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	waveformCacheDir    = "cache/waveforms"
+	defaultWaveformSize = 200
+)
+
+// ComputeWaveform downsamples the audio at streamURL into peak-normalized
+// RMS values, one per bucket, by piping it through ffmpeg as mono 8kHz
+// PCM. buckets <= 0 defaults to defaultWaveformSize.
+func ComputeWaveform(streamURL string, buckets int) ([]float32, error) {
+	if buckets <= 0 {
+		buckets = defaultWaveformSize
+	}
+
+	cmd := exec.Command("ffmpeg", "-i", streamURL, "-ac", "1", "-ar", "8000", "-f", "s16le", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ffmpeg stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	samples, readErr := readPCMSamples(stdout)
+	waitErr := cmd.Wait()
+	if waitErr != nil {
+		return nil, fmt.Errorf("ffmpeg exited with error: %w", waitErr)
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read pcm samples: %w", readErr)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no audio samples decoded from %s", streamURL)
+	}
+
+	return bucketizeRMS(samples, buckets), nil
+}
+
+// readPCMSamples reads raw little-endian s16le samples until EOF.
+func readPCMSamples(r io.Reader) ([]int16, error) {
+	var samples []int16
+	buf := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return samples, err
+		}
+		samples = append(samples, int16(binary.LittleEndian.Uint16(buf)))
+	}
+	return samples, nil
+}
+
+// bucketizeRMS splits samples into `buckets` equal spans and computes the
+// peak-normalized RMS of each, so the result is comparable across tracks
+// regardless of their loudness.
+func bucketizeRMS(samples []int16, buckets int) []float32 {
+	bucketSize := len(samples) / buckets
+	if bucketSize == 0 {
+		bucketSize = 1
+	}
+
+	peaks := make([]float32, 0, buckets)
+	var maxRMS float32
+
+	for i := 0; i < buckets; i++ {
+		start := i * bucketSize
+		if start >= len(samples) {
+			break
+		}
+		end := start + bucketSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		var sumSq float64
+		for _, s := range samples[start:end] {
+			v := float64(s) / 32768.0
+			sumSq += v * v
+		}
+
+		rms := float32(math.Sqrt(sumSq / float64(end-start)))
+		peaks = append(peaks, rms)
+		if rms > maxRMS {
+			maxRMS = rms
+		}
+	}
+
+	if maxRMS > 0 {
+		for i := range peaks {
+			peaks[i] /= maxRMS
+		}
+	}
+
+	return peaks
+}
+
+// LoadOrComputeWaveform returns the waveform cached for trackID, computing
+// it from streamURL via ComputeWaveform and caching the result under
+// cache/waveforms/<trackID>.bin when there's no cache hit yet.
+func LoadOrComputeWaveform(trackID, streamURL string, buckets int) ([]float32, error) {
+	path := waveformCachePath(trackID)
+
+	if cached, err := readWaveformCache(path); err == nil {
+		return cached, nil
+	}
+
+	peaks, err := ComputeWaveform(streamURL, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeWaveformCache(path, peaks); err != nil {
+		logger.WarnF("Failed to cache waveform for %s: %v", trackID, err)
+	}
+
+	return peaks, nil
+}
+
+func waveformCachePath(trackID string) string {
+	return filepath.Join(waveformCacheDir, fmt.Sprintf("%s.bin", trackID))
+}
+
+func readWaveformCache(path string) ([]float32, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var peaks []float32
+	if err := gob.NewDecoder(file).Decode(&peaks); err != nil {
+		return nil, fmt.Errorf("failed to decode cached waveform: %w", err)
+	}
+	return peaks, nil
+}
+
+func writeWaveformCache(path string, peaks []float32) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create waveform cache directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create waveform cache file: %w", err)
+	}
+	defer file.Close()
+
+	return gob.NewEncoder(file).Encode(peaks)
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is part of YukkiMusic.
+ *
+ * YukkiMusic — A Telegram bot that streams music into group voice chats with seamless playback and control.
+ * Copyright (C) 2025 TheTeamVivek
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func encodeSamples(samples []int16) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	for _, s := range samples {
+		var b [2]byte
+		binary.LittleEndian.PutUint16(b[:], uint16(s))
+		buf.Write(b[:])
+	}
+	return buf
+}
+
+func TestReadPCMSamples(t *testing.T) {
+	want := []int16{0, 1, -1, 32767, -32768, 1234}
+	got, err := readPCMSamples(encodeSamples(want))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d samples, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d: expected %d, got %d", i, want[i], got[i])
+		}
+	}
+}
+
+func TestReadPCMSamplesTrailingOddByte(t *testing.T) {
+	// A stream that ends mid-sample (one leftover byte) should stop
+	// cleanly rather than erroring, since ffmpeg's pipe can close at an
+	// arbitrary point.
+	buf := encodeSamples([]int16{42})
+	buf.WriteByte(0x7f)
+
+	got, err := readPCMSamples(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != 42 {
+		t.Fatalf("expected the single complete sample [42], got %v", got)
+	}
+}
+
+func TestBucketizeRMSNormalizesToLoudestBucket(t *testing.T) {
+	// Bucket 0 is loud (max amplitude), bucket 1 is silent.
+	loud := make([]int16, 100)
+	for i := range loud {
+		loud[i] = 32767
+	}
+	quiet := make([]int16, 100)
+
+	samples := append(append([]int16{}, loud...), quiet...)
+	peaks := bucketizeRMS(samples, 2)
+
+	if len(peaks) != 2 {
+		t.Fatalf("expected 2 peaks, got %d", len(peaks))
+	}
+	if math.Abs(float64(peaks[0])-1.0) > 1e-3 {
+		t.Errorf("expected the loudest bucket normalized to ~1.0, got %v", peaks[0])
+	}
+	if peaks[1] != 0 {
+		t.Errorf("expected the silent bucket to be 0, got %v", peaks[1])
+	}
+}
+
+func TestBucketizeRMSAllSilent(t *testing.T) {
+	samples := make([]int16, 40)
+	peaks := bucketizeRMS(samples, 4)
+	if len(peaks) != 4 {
+		t.Fatalf("expected 4 peaks, got %d", len(peaks))
+	}
+	for i, p := range peaks {
+		if p != 0 {
+			t.Errorf("peak %d: expected 0 for all-silent input, got %v", i, p)
+		}
+	}
+}
+
+func TestBucketizeRMSFewerSamplesThanBuckets(t *testing.T) {
+	// Fewer samples than requested buckets: bucketSize floors to 0 and is
+	// clamped to 1, so only as many buckets as samples are produced.
+	samples := []int16{100, -100}
+	peaks := bucketizeRMS(samples, 10)
+	if len(peaks) != len(samples) {
+		t.Fatalf("expected %d peaks (one per sample), got %d", len(samples), len(peaks))
+	}
+}